@@ -0,0 +1,44 @@
+package gormpool
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestConnsGetFIFOReturnsOldest(t *testing.T) {
+	cs := newConns()
+	cs.put(&Conn{Key: "a"})
+	cs.put(&Conn{Key: "b"})
+	cs.put(&Conn{Key: "c"})
+
+	if got := cs.get(FIFO).Key; got != "a" {
+		t.Fatalf("FIFO get = %q, want %q", got, "a")
+	}
+}
+
+func TestConnsGetLIFOReturnsNewest(t *testing.T) {
+	cs := newConns()
+	cs.put(&Conn{Key: "a"})
+	cs.put(&Conn{Key: "b"})
+	cs.put(&Conn{Key: "c"})
+
+	if got := cs.get(LIFO).Key; got != "c" {
+		t.Fatalf("LIFO get = %q, want %q", got, "c")
+	}
+}
+
+func benchmarkConnsGet(b *testing.B, policy IdlePolicy) {
+	cs := newConns()
+	for i := 0; i < 64; i++ {
+		cs.put(&Conn{Key: "conn-" + strconv.Itoa(i)})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn := cs.get(policy)
+		cs.put(conn)
+	}
+}
+
+func BenchmarkConnsGetFIFO(b *testing.B) { benchmarkConnsGet(b, FIFO) }
+func BenchmarkConnsGetLIFO(b *testing.B) { benchmarkConnsGet(b, LIFO) }
@@ -0,0 +1,91 @@
+package gormpool
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// dialLimiter is a simple token-bucket rate limiter guarding how often new
+// connections may be dialed, so a downed database is not stampeded by every
+// Get caller reconnecting at once.
+type dialLimiter struct {
+	mu           sync.Mutex
+	minInterval  time.Duration
+	lastDialTime time.Time
+}
+
+// newDialLimiter builds a dialLimiter allowing at most ratePerSecond dials
+// per second. A non-positive rate disables limiting.
+func newDialLimiter(ratePerSecond float64) *dialLimiter {
+	if ratePerSecond <= 0 {
+		return nil
+	}
+	return &dialLimiter{
+		minInterval: time.Duration(float64(time.Second) / ratePerSecond),
+	}
+}
+
+// wait blocks, if necessary, until the next dial is allowed under the rate
+// limit. A nil dialLimiter never blocks.
+func (l *dialLimiter) wait() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if next := l.lastDialTime.Add(l.minInterval); now.Before(next) {
+		time.Sleep(next.Sub(now))
+		now = time.Now()
+	}
+	l.lastDialTime = now
+}
+
+// dialWithRetry opens a new connection through p.connector, retrying up to
+// p.connectRetry attempts with exponential backoff and jitter, and respecting
+// p.dialLimiter between attempts.
+//
+// dialWithRetry does not touch p.mu or any Pool field other than read-only
+// config: it may sleep for a whole backoff/rate-limit window, and its
+// callers, initConn and replaceIfUnhealthy, always invoke it without p.mu held
+// precisely so that a slow or retrying dial never stalls unrelated
+// Get/Put/Status calls. Callers
+// are responsible for recording the returned error as lastDialErr themselves,
+// under whatever locking discipline they already use.
+func (p *Pool) dialWithRetry() (db *gorm.DB, err error) {
+	attempts := p.connectRetry
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	for attempt := uint(0); attempt < attempts; attempt++ {
+		if attempt > 0 && p.connectBackoff > 0 {
+			time.Sleep(backoffWithJitter(p.connectBackoff, attempt))
+		}
+
+		p.dialLimiter.wait()
+
+		db, err = p.connector.Open()
+		if err == nil {
+			return db, nil
+		}
+	}
+
+	return nil, err
+}
+
+// backoffWithJitter returns base doubled attempt times, plus up to base of
+// random jitter, capped to avoid overflow on large attempt counts.
+func backoffWithJitter(base time.Duration, attempt uint) time.Duration {
+	const maxShift = 20 // caps the delay at roughly base * 2^20
+	if attempt > maxShift {
+		attempt = maxShift
+	}
+	delay := base << attempt
+	return delay + time.Duration(rand.Int63n(int64(base)+1))
+}
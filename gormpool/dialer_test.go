@@ -0,0 +1,99 @@
+package gormpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestGetPastKeepConnDoesNotDeadlock exercises pool growth beyond KeepConn,
+// the path that used to self-deadlock: dialWithRetry took p.mu to record
+// lastDialErr while GetContext's cache-miss branch already held it.
+func TestGetPastKeepConnDoesNotDeadlock(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 1, 2, FIFO)
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := p.Get()
+		done <- err
+		_, err = p.Get()
+		done <- err
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Get: %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Get past KeepConn deadlocked")
+		}
+	}
+}
+
+// TestDialRetryBackoffDoesNotStallUnrelatedPut exercises GetContext's
+// cache-miss branch growing the pool against a connector that fails its
+// first attempt, forcing a real backoff sleep. While that dial is sleeping,
+// an unrelated Put on an already-idle connection must return immediately
+// rather than queue behind the backoff, since initConn no longer holds p.mu
+// across the dial.
+func TestDialRetryBackoffDoesNotStallUnrelatedPut(t *testing.T) {
+	connector := &fakeConnector{
+		failNFn: func(attempt int) error {
+			if attempt == 1 { // the growth Get's first dial attempt
+				return errors.New("dial refused")
+			}
+			return nil
+		},
+	}
+
+	p := &Pool{
+		connector:       connector,
+		keepConn:        1,
+		capacity:        2,
+		maxWaitDuration: time.Second,
+		connectRetry:    2,
+		connectBackoff:  200 * time.Millisecond,
+		idlePolicy:      FIFO,
+		mu:              sync.Mutex{},
+		idleConn:        newConns(),
+		busyConn:        newConns(),
+		ch:              make(chan struct{}, 2),
+		stopCh:          make(chan struct{}),
+		ctx:             context.Background(),
+	}
+	if err := p.initConn(); err != nil {
+		t.Fatalf("initConn: %v", err)
+	}
+
+	idle, err := p.Get() // takes the one keepConn connection
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	growing := make(chan error, 1)
+	go func() {
+		_, err := p.Get() // grows past keepConn: dials, fails once, backs off, retries
+		growing <- err
+	}()
+	time.Sleep(20 * time.Millisecond) // let the growth Get enter its backoff sleep
+
+	putDone := make(chan struct{})
+	go func() {
+		p.Put(idle)
+		close(putDone)
+	}()
+
+	select {
+	case <-putDone:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Put blocked behind an unrelated dial's backoff sleep")
+	}
+
+	if err := <-growing; err != nil {
+		t.Errorf("growth Get: %v", err)
+	}
+}
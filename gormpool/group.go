@@ -0,0 +1,184 @@
+package gormpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNoHealthyEndpoint is returned when a Group has no endpoint left in
+	// rotation to serve a Get from.
+	ErrNoHealthyEndpoint = errors.New("pool: no healthy endpoint available")
+)
+
+// EndpointRole marks whether an Endpoint in a Group serves as the primary
+// (writer) or a replica (reader).
+type EndpointRole int
+
+const (
+	// Primary is the single writer endpoint of a Group.
+	Primary EndpointRole = iota
+	// Replica is a read-only endpoint of a Group.
+	Replica
+)
+
+// Endpoint describes one DSN/endpoint managed by a Group: its pool Options
+// and its role in the read/write topology.
+type Endpoint struct {
+	Role    EndpointRole
+	Options Options
+}
+
+// GroupOptions configures a Group.
+type GroupOptions struct {
+	// Endpoints lists the pools to manage, tagged Primary or Replica. Exactly
+	// one Primary is expected; Replica order determines round-robin order.
+	Endpoints []Endpoint
+
+	// HealthProbe, if set, is run against a freshly borrowed Conn to decide
+	// liveness (e.g. SELECT 1). If nil, only Get errors count toward
+	// failover.
+	HealthProbe func(conn *Conn) error
+
+	// FailoverThreshold is how many consecutive errors on an endpoint before
+	// it is pulled out of rotation. Zero disables failover.
+	FailoverThreshold uint64
+
+	// FailoverCooldown is how long a failed-out endpoint stays out of
+	// rotation before it is retried.
+	FailoverCooldown time.Duration
+}
+
+// member wraps a single endpoint's Pool with the failover bookkeeping a
+// Group needs to decide whether the endpoint is currently in rotation.
+type member struct {
+	pool *Pool
+	role EndpointRole
+
+	mu              sync.Mutex
+	consecutiveErrs uint64
+	downUntil       time.Time
+}
+
+func (m *member) available(now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.downUntil.IsZero() || now.After(m.downUntil)
+}
+
+func (m *member) recordResult(g *Group, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err == nil {
+		m.consecutiveErrs = 0
+		m.downUntil = time.Time{}
+		return
+	}
+
+	m.consecutiveErrs++
+	if g.opts.FailoverThreshold > 0 && m.consecutiveErrs >= g.opts.FailoverThreshold {
+		m.downUntil = time.Now().Add(g.opts.FailoverCooldown)
+	}
+}
+
+// Group manages multiple underlying Pool instances, one per endpoint, and
+// exposes GetWriter/GetReader returning a *Conn from the primary or a
+// load-balanced replica respectively. An endpoint is removed from rotation
+// after FailoverThreshold consecutive errors and re-added once
+// FailoverCooldown has elapsed.
+type Group struct {
+	opts GroupOptions
+
+	writers []*member
+	readers []*member
+
+	nextReader uint64
+}
+
+// NewGroup opens a Pool for every configured endpoint and returns a Group
+// that coordinates them as a single topology-aware client.
+func NewGroup(ctx context.Context, opts GroupOptions) (g *Group, err error) {
+	g = &Group{opts: opts}
+
+	for _, ep := range opts.Endpoints {
+		var pool *Pool
+		pool, err = NewPool(ctx, ep.Options)
+		if err != nil {
+			return nil, err
+		}
+
+		m := &member{pool: pool, role: ep.Role}
+		if ep.Role == Primary {
+			g.writers = append(g.writers, m)
+		} else {
+			g.readers = append(g.readers, m)
+		}
+	}
+
+	return g, nil
+}
+
+// GetWriter returns a Conn from the primary endpoint.
+func (g *Group) GetWriter() (*Conn, error) {
+	return g.get(g.writers)
+}
+
+// GetReader returns a Conn from a load-balanced, healthy replica. If no
+// replica is configured, it falls back to the primary.
+func (g *Group) GetReader() (*Conn, error) {
+	if len(g.readers) == 0 {
+		return g.GetWriter()
+	}
+	return g.get(g.readers)
+}
+
+// get picks the next available member from candidates in round-robin order,
+// skipping ones currently in their failover cooldown, and borrows a Conn
+// from it.
+func (g *Group) get(candidates []*member) (*Conn, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoHealthyEndpoint
+	}
+
+	now := time.Now()
+	start := atomic.AddUint64(&g.nextReader, 1)
+
+	for i := 0; i < len(candidates); i++ {
+		m := candidates[(int(start)+i)%len(candidates)]
+		if !m.available(now) {
+			continue
+		}
+
+		conn, err := m.pool.Get()
+		if err == nil && g.opts.HealthProbe != nil {
+			err = g.opts.HealthProbe(conn)
+			if err != nil {
+				// Probe failed on an otherwise successfully borrowed conn:
+				// return it before moving on, or it leaks out of m.pool's
+				// busyConn forever.
+				m.pool.Put(conn)
+			}
+		}
+		m.recordResult(g, err)
+		if err != nil {
+			continue
+		}
+		return conn, nil
+	}
+
+	return nil, ErrNoHealthyEndpoint
+}
+
+// Close closes every endpoint's Pool.
+func (g *Group) Close() {
+	for _, m := range g.writers {
+		m.pool.Close()
+	}
+	for _, m := range g.readers {
+		m.pool.Close()
+	}
+}
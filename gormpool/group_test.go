@@ -0,0 +1,28 @@
+package gormpool
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestGroupGetReturnsConnOnFailedProbe ensures a Conn borrowed successfully
+// but rejected by HealthProbe is returned to its pool instead of leaking in
+// busyConn forever.
+func TestGroupGetReturnsConnOnFailedProbe(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 1, 1, FIFO)
+	m := &member{pool: p, role: Replica}
+	g := &Group{
+		opts: GroupOptions{
+			HealthProbe: func(*Conn) error { return errors.New("replica lagging") },
+		},
+		readers: []*member{m},
+	}
+
+	if _, err := g.get(g.readers); err != ErrNoHealthyEndpoint {
+		t.Fatalf("get: got err %v, want ErrNoHealthyEndpoint", err)
+	}
+
+	if got := p.Status().BusyConnsState.Size; got != 0 {
+		t.Fatalf("busyConn size = %d, want 0 after failed probe returns the conn", got)
+	}
+}
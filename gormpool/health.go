@@ -0,0 +1,132 @@
+package gormpool
+
+import "time"
+
+// healthCheckLoop periodically pings idle connections and evicts ones that are
+// unhealthy or stale, refilling back up to keepConn. It runs for the lifetime
+// of the pool and exits once the pool's context is done or the pool is closed
+// via Close/CloseWithTimeout.
+func (p *Pool) healthCheckLoop() {
+	ticker := time.NewTicker(p.healthCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.reapIdleConns()
+		}
+	}
+}
+
+// reapIdleConns walks the idle connection list, evicting any connection that
+// fails to ping or has exceeded MaxIdleTime/MaxLifetime, then refills the pool
+// back up to keepConn.
+func (p *Pool) reapIdleConns() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	keys := p.idleConn.orderedKeys()
+
+	for _, key := range keys {
+		conn, ok := p.idleConn.byKey(key)
+		if !ok {
+			continue
+		}
+
+		if p.maxLifetime > 0 && now.Sub(conn.Created) >= p.maxLifetime {
+			p.idleConn.deleteByKey(key)
+			conn.DB.Close()
+			<-p.ch
+			p.evictedConnCount++
+			p.maxLifetimeClosed++
+			continue
+		}
+
+		if p.maxIdleTime > 0 && now.Sub(conn.Updated) >= p.maxIdleTime {
+			p.idleConn.deleteByKey(key)
+			conn.DB.Close()
+			<-p.ch
+			p.evictedConnCount++
+			p.maxIdleTimeClosed++
+			continue
+		}
+
+		if err := conn.DB.DB().Ping(); err != nil {
+			p.idleConn.deleteByKey(key)
+			conn.DB.Close()
+			<-p.ch
+			p.evictedConnCount++
+		}
+	}
+
+	p.mu.Unlock()
+
+	// Always top back up to keepConn, regardless of how many connections were
+	// actually evicted this tick: a refill dial that failed on a previous
+	// tick, or that is still short for any other reason, must keep being
+	// retried rather than only on ticks that also evicted something.
+	//
+	// initConn dials without p.mu held, so the lock is released above and
+	// re-acquired here only to re-check the shortfall: holding it across the
+	// dial would stall every other caller of Get, Put and Status for the
+	// whole retry/backoff window on a downed database.
+	for {
+		p.mu.Lock()
+		needMore := !p.closed && p.idleConn.size < p.keepConn
+		p.mu.Unlock()
+		if !needMore {
+			break
+		}
+		if err := p.initConn(); err != nil {
+			break
+		}
+	}
+}
+
+// replaceIfUnhealthy pings conn and, if the ping fails, closes it and opens a
+// fresh replacement in its place through the same retry/rate-limited dial
+// path as initConn. Used by Get when PingOnBorrow is enabled. conn is already
+// indexed in p.busyConn at this point (borrowed via the idle-hit path in
+// GetContext); p.ch is not touched here, since it only tracks idleConn, not
+// busyConn.
+func (p *Pool) replaceIfUnhealthy(conn *Conn) (*Conn, error) {
+	if err := conn.DB.DB().Ping(); err == nil {
+		return conn, nil
+	}
+
+	p.mu.Lock()
+	p.evictedConnCount++
+	p.mu.Unlock()
+	conn.DB.Close()
+
+	fresh, err := p.dialWithRetry()
+
+	// Like initConn, replaceIfUnhealthy runs without p.mu held (it is
+	// called from GetContext after the idle-hit path already released the
+	// lock), so it must take p.mu itself before touching shared state.
+	p.mu.Lock()
+	p.lastDialErr = err
+	if err != nil {
+		// The replacement dial failed too: conn is unusable and must not be
+		// left behind as a ghost entry in p.busyConn, or it would
+		// permanently shrink the pool's effective capacity.
+		p.busyConn.deleteByKey(conn.Key)
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	conn.DB = fresh
+	conn.Created = time.Now()
+	conn.Updated = time.Now()
+	return conn, nil
+}
@@ -0,0 +1,100 @@
+package gormpool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestReapIdleConnsRefillDoesNotDrainChannelTwice exercises the idle-timeout
+// eviction + refill path. The refill loop used to drain an extra p.ch token
+// per refilled connection beyond what initConn already balanced, eventually
+// starving idle-hit Gets even though idle connections existed.
+func TestReapIdleConnsRefillDoesNotDrainChannelTwice(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 2, 2, FIFO)
+	p.maxIdleTime = time.Millisecond
+
+	time.Sleep(5 * time.Millisecond)
+	p.reapIdleConns()
+
+	if got := p.idleConn.size; got != p.keepConn {
+		t.Fatalf("idleConn.size = %d, want %d after refill", got, p.keepConn)
+	}
+	if got := len(p.ch); uint64(got) != p.idleConn.size {
+		t.Fatalf("len(p.ch) = %d, want %d to match idleConn.size", got, p.idleConn.size)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Get()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Get: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Get blocked despite idle connections being available")
+	}
+}
+
+// TestReapIdleConnsRefillTargetsKeepConnRegardlessOfEvictionCount exercises a
+// tick that evicts a single connection while the idle pool is already two
+// short of keepConn (e.g. a previous tick's refill dial failed partway
+// through). The refill loop used to be capped at the eviction count for this
+// tick, so it would only replace the one connection just evicted and leave
+// the pool permanently under keepConn; it must instead top back up to
+// keepConn in a single tick.
+func TestReapIdleConnsRefillTargetsKeepConnRegardlessOfEvictionCount(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 3, 3, FIFO)
+	p.maxIdleTime = time.Millisecond
+
+	// Simulate a prior tick's refill coming up short: drop the idle pool to
+	// one connection without going through reapIdleConns.
+	p.mu.Lock()
+	keys := p.idleConn.orderedKeys()
+	for _, key := range keys[:2] {
+		conn, _ := p.idleConn.byKey(key)
+		p.idleConn.deleteByKey(key)
+		conn.DB.Close()
+		<-p.ch
+	}
+	p.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+	p.reapIdleConns() // evicts the single remaining (now stale) idle conn
+
+	if got := p.idleConn.size; got != p.keepConn {
+		t.Fatalf("idleConn.size = %d, want %d after refill", got, p.keepConn)
+	}
+}
+
+// TestReplaceIfUnhealthyCleansUpGhostOnReconnectFailure ensures that when a
+// PingOnBorrow replacement dial also fails, the original connection is
+// removed from busyConn instead of being left behind as a ghost entry that
+// would permanently shrink the pool's effective capacity.
+func TestReplaceIfUnhealthyCleansUpGhostOnReconnectFailure(t *testing.T) {
+	connector := &fakeConnector{}
+	p := newTestPool(t, connector, 1, 1, FIFO)
+
+	conn, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	conn.DB.Close() // force the next ping to fail
+
+	connector.failNFn = func(int) error { return errors.New("dial refused") }
+
+	if _, err := p.replaceIfUnhealthy(conn); err == nil {
+		t.Fatal("expected replaceIfUnhealthy to fail when reconnect dial fails")
+	}
+
+	p.mu.Lock()
+	_, stillPresent := p.busyConn.byKey(conn.Key)
+	p.mu.Unlock()
+	if stillPresent {
+		t.Fatal("conn left behind as a ghost entry in busyConn")
+	}
+}
@@ -0,0 +1,61 @@
+package gormpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+// fakeConnector implements sqls.Connector with an in-memory sqlite-backed
+// *gorm.DB, so pool internals can be exercised without a real database.
+type fakeConnector struct {
+	mu      sync.Mutex
+	opens   int
+	failNFn func(attempt int) error // optional: return non-nil to fail that attempt
+}
+
+func (c *fakeConnector) Open() (*gorm.DB, error) {
+	c.mu.Lock()
+	attempt := c.opens
+	c.opens++
+	c.mu.Unlock()
+
+	if c.failNFn != nil {
+		if err := c.failNFn(attempt); err != nil {
+			return nil, err
+		}
+	}
+	return gorm.Open("sqlite3", ":memory:")
+}
+
+// newTestPool builds a Pool directly (bypassing Options.validate, which
+// requires a real godbpool.SQLType) wired to a fakeConnector, mirroring what
+// NewPool assembles internally.
+func newTestPool(t *testing.T, connector *fakeConnector, keepConn, capacity uint64, policy IdlePolicy) *Pool {
+	t.Helper()
+
+	p := &Pool{
+		connector:       connector,
+		keepConn:        keepConn,
+		capacity:        capacity,
+		maxWaitDuration: time.Second,
+		idlePolicy:      policy,
+		mu:              sync.Mutex{},
+		idleConn:        newConns(),
+		busyConn:        newConns(),
+		ch:              make(chan struct{}, capacity),
+		stopCh:          make(chan struct{}),
+		ctx:             context.Background(),
+	}
+
+	for i := uint64(0); i < keepConn; i++ {
+		if err := p.initConn(); err != nil {
+			t.Fatalf("initConn: %v", err)
+		}
+	}
+	return p
+}
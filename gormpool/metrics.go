@@ -0,0 +1,73 @@
+package gormpool
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	descIdle = prometheus.NewDesc(
+		"godbpool_idle_connections", "Number of idle connections in the pool.", nil, nil)
+	descBusy = prometheus.NewDesc(
+		"godbpool_busy_connections", "Number of busy connections in the pool.", nil, nil)
+	descCapacity = prometheus.NewDesc(
+		"godbpool_max_open_connections", "Maximum number of open connections allowed.", nil, nil)
+	descWaitCount = prometheus.NewDesc(
+		"godbpool_wait_count_total", "Total number of connections waited for.", nil, nil)
+	descWaitDuration = prometheus.NewDesc(
+		"godbpool_wait_duration_seconds_total", "Total time spent waiting for a connection.", nil, nil)
+	descHits = prometheus.NewDesc(
+		"godbpool_hits_total", "Total number of Get calls served by reusing an idle connection.", nil, nil)
+	descMisses = prometheus.NewDesc(
+		"godbpool_misses_total", "Total number of Get calls served by opening a new connection.", nil, nil)
+	descTimeouts = prometheus.NewDesc(
+		"godbpool_timeouts_total", "Total number of Get calls that timed out.", nil, nil)
+	descMaxIdleClosed = prometheus.NewDesc(
+		"godbpool_max_idle_closed_total", "Total number of connections closed for exceeding KeepConn.", nil, nil)
+	descMaxLifetimeClosed = prometheus.NewDesc(
+		"godbpool_max_lifetime_closed_total", "Total number of connections closed for exceeding MaxLifetime.", nil, nil)
+	descMaxIdleTimeClosed = prometheus.NewDesc(
+		"godbpool_max_idle_time_closed_total", "Total number of connections closed for exceeding MaxIdleTime.", nil, nil)
+)
+
+// poolCollector adapts a Pool's Status() snapshot to prometheus.Collector so
+// a Pool can be registered directly with a prometheus.Registerer.
+type poolCollector struct {
+	pool *Pool
+}
+
+// Collector returns a prometheus.Collector exposing this pool's counters, so
+// callers can do registry.MustRegister(pool.Collector()) without writing
+// their own glue.
+func (p *Pool) Collector() prometheus.Collector {
+	return &poolCollector{pool: p}
+}
+
+// Describe implements prometheus.Collector.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descIdle
+	ch <- descBusy
+	ch <- descCapacity
+	ch <- descWaitCount
+	ch <- descWaitDuration
+	ch <- descHits
+	ch <- descMisses
+	ch <- descTimeouts
+	ch <- descMaxIdleClosed
+	ch <- descMaxLifetimeClosed
+	ch <- descMaxIdleTimeClosed
+}
+
+// Collect implements prometheus.Collector.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	ps := c.pool.Status()
+
+	ch <- prometheus.MustNewConstMetric(descIdle, prometheus.GaugeValue, float64(ps.IdleConnsState.Size))
+	ch <- prometheus.MustNewConstMetric(descBusy, prometheus.GaugeValue, float64(ps.BusyConnsState.Size))
+	ch <- prometheus.MustNewConstMetric(descCapacity, prometheus.GaugeValue, float64(ps.Capacity))
+	ch <- prometheus.MustNewConstMetric(descWaitCount, prometheus.CounterValue, float64(ps.WaitCount))
+	ch <- prometheus.MustNewConstMetric(descWaitDuration, prometheus.CounterValue, ps.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(descHits, prometheus.CounterValue, float64(ps.Hits))
+	ch <- prometheus.MustNewConstMetric(descMisses, prometheus.CounterValue, float64(ps.Misses))
+	ch <- prometheus.MustNewConstMetric(descTimeouts, prometheus.CounterValue, float64(ps.Timeouts))
+	ch <- prometheus.MustNewConstMetric(descMaxIdleClosed, prometheus.CounterValue, float64(ps.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(descMaxLifetimeClosed, prometheus.CounterValue, float64(ps.MaxLifetimeClosed))
+	ch <- prometheus.MustNewConstMetric(descMaxIdleTimeClosed, prometheus.CounterValue, float64(ps.MaxIdleTimeClosed))
+}
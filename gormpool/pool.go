@@ -1,6 +1,7 @@
 package gormpool
 
 import (
+	"container/list"
 	"context"
 	"errors"
 	"github.com/ALiuGuanyan/godbpool"
@@ -17,10 +18,23 @@ import (
 )
 
 var (
-	// ErrGetFromClosedPool if try to get a Conn from the closed pool
-	ErrGetFromClosedPool = errors.New("pool: get from closed pool")
-	// ErrExceedingMaxWaitingDuration try to get a Conn from the pool but exceeding the max waiting time
-	ErrExceedingMaxWaitingDuration = errors.New("pool: exceeding the maximum waiting duration")
+	// ErrPoolClosed is returned when Get/GetContext is called on a pool whose
+	// own context has been canceled or that has been explicitly closed.
+	ErrPoolClosed = errors.New("pool: get from closed pool")
+	// ErrCtxCanceled is returned when the context passed to GetContext is
+	// canceled or exceeds its deadline before a connection becomes available.
+	ErrCtxCanceled = errors.New("pool: caller context canceled")
+	// ErrPoolTimeout is returned when MaxWaitDuration elapses before a
+	// connection becomes available.
+	ErrPoolTimeout = errors.New("pool: exceeding the maximum waiting duration")
+	// ErrGetFromClosedPool is a deprecated alias for ErrPoolClosed.
+	//
+	// Deprecated: use ErrPoolClosed instead.
+	ErrGetFromClosedPool = ErrPoolClosed
+	// ErrExceedingMaxWaitingDuration is a deprecated alias for ErrPoolTimeout.
+	//
+	// Deprecated: use ErrPoolTimeout instead.
+	ErrExceedingMaxWaitingDuration = ErrPoolTimeout
 	// ErrSQLType try to connect the SQL which does not support by the pool
 	ErrSQLType = errors.New("pool: sql type does not support")
 	// ErrKeepLTCapacity keepConn larger than the pool capacity
@@ -31,6 +45,16 @@ var (
 	ErrEmptyArgs = errors.New("pool: args cannot be empty")
 )
 
+// IdlePolicy selects which idle connection Get hands out first.
+type IdlePolicy int
+
+const (
+	// FIFO hands out the longest-idle connection first.
+	FIFO IdlePolicy = iota
+	// LIFO hands out the most-recently-returned connection first.
+	LIFO
+)
+
 // Options is pool configuration
 type Options struct {
 	// DB type, e.g. MySQL, SQLite3...
@@ -52,6 +76,45 @@ type Options struct {
 	// Maximum waiting duration to get a conn from the pool
 	MaxWaitDuration time.Duration
 
+	// HealthCheckPeriod is how often the background maintenance goroutine pings
+	// idle connections and reaps ones that are unhealthy or stale. Zero disables
+	// the health-check goroutine entirely.
+	HealthCheckPeriod time.Duration
+
+	// MaxIdleTime is the maximum duration a connection may sit idle before the
+	// health checker evicts it. Zero means idle connections are never evicted
+	// for being idle too long.
+	MaxIdleTime time.Duration
+
+	// MaxLifetime is the maximum duration a connection may exist, whether idle
+	// or busy, before the health checker evicts it. Zero means no limit.
+	MaxLifetime time.Duration
+
+	// PingOnBorrow, when true, pings a connection with DB.DB().Ping() before
+	// handing it out from Get, discarding and replacing it if the ping fails.
+	PingOnBorrow bool
+
+	// ConnectRetry is the maximum number of attempts made to open a new
+	// connection before giving up. Zero or one means no retry.
+	ConnectRetry uint
+
+	// ConnectBackoff is the base delay used for exponential backoff with
+	// jitter between connect retries. Zero disables the delay between
+	// attempts.
+	ConnectBackoff time.Duration
+
+	// MaxDialsPerSecond caps how many new connections may be dialed per
+	// second, smoothing out reconnect storms when many Get callers arrive
+	// during an outage. Zero means unlimited.
+	MaxDialsPerSecond float64
+
+	// IdlePolicy controls which idle connection is handed out first: FIFO
+	// (the default) hands out the longest-idle connection first, maximizing
+	// rotation; LIFO hands out the most-recently-returned connection first,
+	// favoring warm caches and TLS session reuse while leaving cold
+	// connections at the front for the idle-timeout reaper to prune.
+	IdlePolicy IdlePolicy
+
 	connector sqls.Connector
 }
 
@@ -103,6 +166,22 @@ type Pool struct {
 
 	maxWaitDuration time.Duration
 
+	healthCheckPeriod time.Duration
+
+	maxIdleTime time.Duration
+
+	maxLifetime time.Duration
+
+	pingOnBorrow bool
+
+	connectRetry uint
+
+	connectBackoff time.Duration
+
+	dialLimiter *dialLimiter
+
+	idlePolicy IdlePolicy
+
 	mu sync.Mutex // mu protects the following fields
 
 	idleConn *conns // idle connections in this pool
@@ -111,6 +190,7 @@ type Pool struct {
 
 	closed           bool          // set to true when the pool is closed.
 	ch               chan struct{} // limits open connections when p.Wait is true
+	stopCh           chan struct{} // closed by Close/CloseWithTimeout to stop healthCheckLoop
 	currentWaitCount uint64        // current number of connections waited for.
 	totalWaitCount   uint64        // total number of connections waited for.
 	waitDuration     time.Duration // total time waited for new connections.
@@ -120,6 +200,36 @@ type Pool struct {
 	// then droppedGetCount will increase by 1
 	droppedGetCount uint64
 
+	// evictedConnCount counts connections removed by the health checker, either
+	// because a ping failed or because they exceeded MaxIdleTime/MaxLifetime.
+	evictedConnCount uint64
+
+	// maxIdleClosed counts connections closed by Put because the idle pool was
+	// already at KeepConn capacity.
+	maxIdleClosed uint64
+
+	// maxLifetimeClosed counts connections closed by the health checker for
+	// exceeding MaxLifetime.
+	maxLifetimeClosed uint64
+
+	// maxIdleTimeClosed counts connections closed by the health checker for
+	// exceeding MaxIdleTime.
+	maxIdleTimeClosed uint64
+
+	// hits counts Get/GetContext calls served by reusing an idle connection.
+	hits uint64
+
+	// misses counts Get/GetContext calls served by opening a new connection.
+	misses uint64
+
+	// timeouts counts Get/GetContext calls that failed with ErrPoolTimeout.
+	timeouts uint64
+
+	// lastDialErr is the most recent error encountered while dialing a new
+	// connection, surfaced via Status() so operators can see why the pool is
+	// struggling without scraping logs.
+	lastDialErr error
+
 	ctx context.Context
 }
 
@@ -131,23 +241,40 @@ func NewPool(ctx context.Context, opts Options) (p *Pool, err error) {
 	}
 
 	p = &Pool{
-		Type:             opts.Type,
-		Args:             opts.Args,
-		keyFunc:          opts.KeyFunc,
-		connector:        opts.connector,
-		keepConn:         opts.KeepConn,
-		capacity:         opts.Capacity,
-		maxWaitDuration:  opts.MaxWaitDuration,
-		mu:               sync.Mutex{},
-		idleConn:         newConns(),
-		busyConn:         newConns(),
-		closed:           false,
-		ch:               make(chan struct{}, opts.Capacity),
-		currentWaitCount: 0,
-		totalWaitCount:   0,
-		waitDuration:     0,
-		droppedGetCount:  0,
-		ctx:              ctx,
+		Type:              opts.Type,
+		Args:              opts.Args,
+		keyFunc:           opts.KeyFunc,
+		connector:         opts.connector,
+		keepConn:          opts.KeepConn,
+		capacity:          opts.Capacity,
+		maxWaitDuration:   opts.MaxWaitDuration,
+		healthCheckPeriod: opts.HealthCheckPeriod,
+		maxIdleTime:       opts.MaxIdleTime,
+		maxLifetime:       opts.MaxLifetime,
+		pingOnBorrow:      opts.PingOnBorrow,
+		connectRetry:      opts.ConnectRetry,
+		connectBackoff:    opts.ConnectBackoff,
+		dialLimiter:       newDialLimiter(opts.MaxDialsPerSecond),
+		idlePolicy:        opts.IdlePolicy,
+		mu:                sync.Mutex{},
+		idleConn:          newConns(),
+		busyConn:          newConns(),
+		closed:            false,
+		ch:                make(chan struct{}, opts.Capacity),
+		stopCh:            make(chan struct{}),
+		currentWaitCount:  0,
+		totalWaitCount:    0,
+		waitDuration:      0,
+		droppedGetCount:   0,
+		evictedConnCount:  0,
+		maxIdleClosed:     0,
+		maxLifetimeClosed: 0,
+		maxIdleTimeClosed: 0,
+		hits:              0,
+		misses:            0,
+		timeouts:          0,
+		lastDialErr:       nil,
+		ctx:               ctx,
 	}
 
 	if p.keepConn == 0 {
@@ -172,21 +299,30 @@ func NewPool(ctx context.Context, opts Options) (p *Pool, err error) {
 		default:
 		}
 	}()
+
+	if p.healthCheckPeriod > 0 {
+		go p.healthCheckLoop()
+	}
+
 	return p, nil
 }
 
-// called when do not know DBType and DBArgs are valid
+// initConn dials a new connection and adds it to the idle pool. It must be
+// called without p.mu held: dialing may block for a whole retry/backoff
+// window, and initConn takes p.mu itself (a non-reentrant mutex) only for
+// the brief commit step once the dial has settled.
 func (p *Pool) initConn() error {
-	var (
-		key string
-		db  *gorm.DB
-		err error
-	)
+	var key string
 
-	db, err = p.connector.Open()
+	db, err := p.dialWithRetry()
+
+	p.mu.Lock()
+	p.lastDialErr = err
 	if err != nil {
+		p.mu.Unlock()
 		return err
 	}
+	defer p.mu.Unlock()
 
 	if p.keyFunc == nil {
 		key = strings.ReplaceAll(uuid.New().String(), "-", "")
@@ -217,69 +353,115 @@ func (p *Pool) checkArgs() error {
 	return nil
 }
 
-// Get a SQL connection from the pool
+// Get a SQL connection from the pool. It is equivalent to GetContext(p.ctx),
+// so it only stops waiting when the pool itself is closed or MaxWaitDuration
+// elapses.
 func (p *Pool) Get() (conn *Conn, err error) {
+	return p.GetContext(p.ctx)
+}
+
+// GetContext gets a SQL connection from the pool, honoring ctx in addition to
+// the pool's own MaxWaitDuration and lifetime. If ctx is done before a
+// connection becomes available, GetContext returns ErrCtxCanceled; if the
+// pool itself is closed, it returns ErrPoolClosed; if MaxWaitDuration elapses
+// first, it returns ErrPoolTimeout.
+func (p *Pool) GetContext(ctx context.Context) (conn *Conn, err error) {
 	select {
 	case <-p.ctx.Done():
 		p.mu.Lock()
 		p.droppedGetCount++
 		p.mu.Unlock()
-		return nil, ErrGetFromClosedPool
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ErrCtxCanceled
 	default:
-		p.mu.Lock()
-		if p.closed {
-			p.droppedGetCount++
-			p.mu.Unlock()
-			return nil, ErrGetFromClosedPool
-		}
+	}
 
-		if p.idleConn.size > 0 {
-			conn = p.get()
-			<-p.ch
-			p.mu.Unlock()
-			return conn, nil
-		}
+	p.mu.Lock()
+	if p.closed {
+		p.droppedGetCount++
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
 
-		if p.busyConn.size < p.capacity {
-			err = p.initConn()
+	if p.idleConn.size > 0 {
+		conn = p.get()
+		<-p.ch
+		p.hits++
+		p.mu.Unlock()
+		if p.pingOnBorrow {
+			conn, err = p.replaceIfUnhealthy(conn)
 			if err != nil {
 				return nil, err
 			}
-			conn = p.get()
-			<-p.ch
-			p.mu.Unlock()
-			return conn, nil
 		}
+		return conn, nil
+	}
 
-		timer := time.NewTimer(p.maxWaitDuration)
-		start := time.Now()
-		p.currentWaitCount++
-		p.totalWaitCount++
+	if p.busyConn.size < p.capacity {
+		// initConn dials without p.mu held, so release it here: otherwise a
+		// slow or retrying dial would stall every other caller of Get, Put
+		// and Status for the whole backoff window, not just this one.
 		p.mu.Unlock()
-		select {
-		case <-p.ctx.Done():
-			p.mu.Unlock()
-			return nil, ErrGetFromClosedPool
-		case <-p.ch:
-			p.mu.Lock()
-			conn = p.get()
-			p.waitDuration += time.Since(start)
-			p.currentWaitCount--
-			p.mu.Unlock()
-			return conn, nil
-		case <-timer.C:
-			p.mu.Lock()
-			p.waitDuration += time.Since(start)
-			p.droppedGetCount++
-			p.currentWaitCount--
-			p.mu.Unlock()
-			return nil, ErrExceedingMaxWaitingDuration
+		err = p.initConn()
+		if err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		conn = p.get()
+		<-p.ch
+		p.misses++
+		p.mu.Unlock()
+		return conn, nil
+	}
+
+	timer := time.NewTimer(p.maxWaitDuration)
+	defer timer.Stop()
+	start := time.Now()
+	p.currentWaitCount++
+	p.totalWaitCount++
+	p.mu.Unlock()
+	select {
+	case <-p.ctx.Done():
+		p.mu.Lock()
+		p.waitDuration += time.Since(start)
+		p.droppedGetCount++
+		p.currentWaitCount--
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.waitDuration += time.Since(start)
+		p.currentWaitCount--
+		p.mu.Unlock()
+		return nil, ErrCtxCanceled
+	case <-p.ch:
+		p.mu.Lock()
+		conn = p.get()
+		p.waitDuration += time.Since(start)
+		p.currentWaitCount--
+		p.hits++
+		p.mu.Unlock()
+		if p.pingOnBorrow {
+			conn, err = p.replaceIfUnhealthy(conn)
+			if err != nil {
+				return nil, err
+			}
 		}
+		return conn, nil
+	case <-timer.C:
+		p.mu.Lock()
+		p.waitDuration += time.Since(start)
+		p.droppedGetCount++
+		p.timeouts++
+		p.currentWaitCount--
+		p.mu.Unlock()
+		return nil, ErrPoolTimeout
 	}
 }
 
 func (p *Pool) get() (conn *Conn) {
-	conn = p.idleConn.get()
+	conn = p.idleConn.get(p.idlePolicy)
 	p.busyConn.put(conn)
 	return conn
 }
@@ -293,6 +475,9 @@ func (p *Pool) Put(conn *Conn) {
 		p.idleConn.put(conn)
 		p.ch <- struct{}{}
 	} else {
+		if !p.closed {
+			p.maxIdleClosed++
+		}
 		conn.DB.Close()
 		conn = nil
 	}
@@ -302,9 +487,52 @@ func (p *Pool) Put(conn *Conn) {
 // Close the pool
 func (p *Pool) Close() {
 	p.mu.Lock()
+	alreadyClosed := p.closed
 	p.closed = true
 	p.idleConn.close()
 	p.mu.Unlock()
+
+	if !alreadyClosed {
+		close(p.stopCh)
+	}
+}
+
+// CloseWithTimeout closes the pool, first waiting for busy connections to be
+// returned via Put until ctx is done, then force-closing whatever is still
+// outstanding. Use this for graceful shutdown so in-flight queries are not
+// cut off mid-use.
+func (p *Pool) CloseWithTimeout(ctx context.Context) {
+	p.mu.Lock()
+	alreadyClosed := p.closed
+	p.closed = true
+	p.mu.Unlock()
+
+	if !alreadyClosed {
+		close(p.stopCh)
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+wait:
+	for {
+		p.mu.Lock()
+		busy := p.busyConn.size
+		p.mu.Unlock()
+		if busy == 0 {
+			break wait
+		}
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-ticker.C:
+		}
+	}
+
+	p.mu.Lock()
+	p.idleConn.close()
+	p.busyConn.close()
+	p.mu.Unlock()
 }
 
 // Status shows the current pool status of the pool
@@ -313,11 +541,11 @@ func (p *Pool) Status() (ps PoolState) {
 	ps = PoolState{
 		IdleConnsState: ConnsState{
 			Size:  p.idleConn.size,
-			Conns: p.idleConn.conns,
+			Conns: p.idleConn.snapshot(),
 		},
 		BusyConnsState: ConnsState{
 			Size:  p.busyConn.size,
-			Conns: p.busyConn.conns,
+			Conns: p.busyConn.snapshot(),
 		},
 		Capacity:             p.capacity,
 		Closed:               p.closed,
@@ -326,14 +554,28 @@ func (p *Pool) Status() (ps PoolState) {
 		CurrentWaitCount:     p.currentWaitCount,
 		TotalWaitCount:       p.totalWaitCount,
 		DroppedGetCount:      p.droppedGetCount,
+		EvictedConnCount:     p.evictedConnCount,
+		MaxIdleClosed:        p.maxIdleClosed,
+		MaxLifetimeClosed:    p.maxLifetimeClosed,
+		MaxIdleTimeClosed:    p.maxIdleTimeClosed,
+		WaitCount:            p.totalWaitCount,
+		WaitDuration:         p.waitDuration,
+		Hits:                 p.hits,
+		Misses:               p.misses,
+		Timeouts:             p.timeouts,
+		LastDialErr:          p.lastDialErr,
 	}
 	p.mu.Unlock()
 	return ps
 }
 
+// conns is an O(1) LRU-style container backed by container/list: get, put
+// and deleteByKey are all O(1), and the list order (front = oldest/head,
+// back = most recently put) supports both FIFO and LIFO retrieval plus
+// ordered iteration for the idle-timeout reaper.
 type conns struct {
-	keys  []string
-	conns map[string]*Conn
+	order *list.List
+	index map[string]*list.Element
 	size  uint64
 }
 
@@ -343,21 +585,34 @@ type Conn struct {
 	Key              string
 	Created, Updated time.Time
 	UsageCounter     uint64
+
+	// listElement points at this Conn's node in whichever conns container
+	// (idleConn or busyConn) currently holds it.
+	listElement *list.Element
 }
 
 func newConns() *conns {
 	return &conns{
-		keys:  []string{},
-		conns: map[string]*Conn{},
+		order: list.New(),
+		index: map[string]*list.Element{},
 		size:  0,
 	}
 }
 
-func (cs *conns) get() (conn *Conn) {
-	key := cs.keys[0]
-	cs.keys = cs.keys[1:]
-	conn = cs.conns[key]
-	delete(cs.conns, key)
+// get pops a connection per policy: FIFO takes the front (longest idle),
+// LIFO takes the back (most recently returned).
+func (cs *conns) get(policy IdlePolicy) (conn *Conn) {
+	var el *list.Element
+	if policy == LIFO {
+		el = cs.order.Back()
+	} else {
+		el = cs.order.Front()
+	}
+
+	conn = el.Value.(*Conn)
+	cs.order.Remove(el)
+	delete(cs.index, conn.Key)
+	conn.listElement = nil
 	cs.size--
 	conn.UsageCounter++
 	conn.Updated = time.Now()
@@ -365,33 +620,65 @@ func (cs *conns) get() (conn *Conn) {
 }
 
 func (cs *conns) put(conn *Conn) {
-	cs.keys = append(cs.keys, conn.Key)
-	cs.conns[conn.Key] = conn
+	conn.listElement = cs.order.PushBack(conn)
+	cs.index[conn.Key] = conn.listElement
 	cs.size++
 	conn.Updated = time.Now()
 }
 
+// deleteByKey removes the connection with the given key in O(1). It is a
+// no-op if key is not present.
 func (cs *conns) deleteByKey(key string) {
-	keys := make([]string, cs.size-1)
-	for _, val := range cs.keys {
-		if val != key {
-			keys = append(keys, val)
-		} else {
-			cs.conns[val].Updated = time.Now()
-		}
+	el, ok := cs.index[key]
+	if !ok {
+		return
 	}
-	cs.keys = keys
+
+	conn := el.Value.(*Conn)
+	cs.order.Remove(el)
+	delete(cs.index, key)
+	conn.listElement = nil
+	conn.Updated = time.Now()
 	cs.size--
-	delete(cs.conns, key)
 }
 
-func (cs *conns) close() {
-	for _, conn := range cs.conns {
-		conn.DB.Close()
+// orderedKeys returns the keys currently held, front to back, as a snapshot
+// safe to range over while the caller mutates cs via deleteByKey.
+func (cs *conns) orderedKeys() []string {
+	keys := make([]string, 0, cs.size)
+	for el := cs.order.Front(); el != nil; el = el.Next() {
+		keys = append(keys, el.Value.(*Conn).Key)
+	}
+	return keys
+}
+
+// byKey returns the connection with the given key, if present.
+func (cs *conns) byKey(key string) (conn *Conn, ok bool) {
+	el, ok := cs.index[key]
+	if !ok {
+		return nil, false
 	}
-	for _, key := range cs.keys {
-		delete(cs.conns, key)
+	return el.Value.(*Conn), true
+}
+
+// snapshot returns a plain map of the connections currently held, for
+// read-only reporting such as PoolState.
+func (cs *conns) snapshot() map[string]*Conn {
+	m := make(map[string]*Conn, cs.size)
+	for el := cs.order.Front(); el != nil; el = el.Next() {
+		conn := el.Value.(*Conn)
+		m[conn.Key] = conn
+	}
+	return m
+}
+
+func (cs *conns) close() {
+	for el := cs.order.Front(); el != nil; el = el.Next() {
+		el.Value.(*Conn).DB.Close()
 	}
+	cs.order.Init()
+	cs.index = map[string]*list.Element{}
+	cs.size = 0
 }
 
 // ConnsState shows Connections List state
@@ -411,4 +698,34 @@ type PoolState struct {
 	CurrentWaitCount     uint64
 	TotalWaitCount       uint64
 	DroppedGetCount      uint64
+	EvictedConnCount     uint64
+
+	// MaxIdleClosed is the number of connections closed by Put because the
+	// idle pool was already at KeepConn capacity.
+	MaxIdleClosed uint64
+	// MaxLifetimeClosed is the number of connections closed by the health
+	// checker for exceeding Options.MaxLifetime.
+	MaxLifetimeClosed uint64
+	// MaxIdleTimeClosed is the number of connections closed by the health
+	// checker for exceeding Options.MaxIdleTime.
+	MaxIdleTimeClosed uint64
+	// WaitCount is the total number of Get/GetContext calls that had to wait
+	// for a connection to become available. Mirrors database/sql's
+	// DBStats.WaitCount.
+	WaitCount uint64
+	// WaitDuration is the cumulative time spent waiting for a connection.
+	// Mirrors database/sql's DBStats.WaitDuration.
+	WaitDuration time.Duration
+	// Hits is the number of Get/GetContext calls served by reusing an idle
+	// connection.
+	Hits uint64
+	// Misses is the number of Get/GetContext calls served by opening a new
+	// connection.
+	Misses uint64
+	// Timeouts is the number of Get/GetContext calls that failed with
+	// ErrPoolTimeout.
+	Timeouts uint64
+	// LastDialErr is the most recent error encountered while dialing a new
+	// connection, or nil if the last dial succeeded (or none has happened).
+	LastDialErr error
 }
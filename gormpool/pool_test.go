@@ -0,0 +1,69 @@
+package gormpool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetContextCanceledReturnsErrCtxCanceled(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 0, 1, FIFO)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := p.GetContext(ctx); err != ErrCtxCanceled {
+		t.Fatalf("GetContext: got err %v, want ErrCtxCanceled", err)
+	}
+}
+
+func TestGetContextWaiterCanceledIndependentlyOfPool(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 1, 1, FIFO)
+	if _, err := p.Get(); err != nil { // exhaust the single connection
+		t.Fatalf("Get: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.GetContext(ctx); err != ErrCtxCanceled {
+		t.Fatalf("GetContext: got err %v, want ErrCtxCanceled", err)
+	}
+}
+
+func TestCloseWithTimeoutForceClosesAfterDeadline(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 1, 1, FIFO)
+	if _, err := p.Get(); err != nil { // leave one conn permanently busy
+		t.Fatalf("Get: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	p.CloseWithTimeout(ctx)
+
+	if !p.closed {
+		t.Fatal("pool not marked closed")
+	}
+	if got := p.busyConn.size; got != 0 {
+		t.Fatalf("busyConn.size = %d, want 0 after force-close", got)
+	}
+}
+
+func TestStatusHitsAndMisses(t *testing.T) {
+	p := newTestPool(t, &fakeConnector{}, 1, 2, FIFO)
+
+	if _, err := p.Get(); err != nil { // idle hit
+		t.Fatalf("Get: %v", err)
+	}
+	if _, err := p.Get(); err != nil { // cache miss, dials a new conn
+		t.Fatalf("Get: %v", err)
+	}
+
+	ps := p.Status()
+	if ps.Hits != 1 {
+		t.Errorf("Hits = %d, want 1", ps.Hits)
+	}
+	if ps.Misses != 1 {
+		t.Errorf("Misses = %d, want 1", ps.Misses)
+	}
+}